@@ -2,6 +2,7 @@ package jsonapi
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -330,6 +331,48 @@ func (n *NumberPost) SetID(ID string) error {
 	return nil
 }
 
+type PrecisePost struct {
+	ID     string `json:"-"`
+	Title  string
+	Amount json.Number
+}
+
+func (p *PrecisePost) SetID(ID string) error {
+	p.ID = ID
+
+	return nil
+}
+
+type PostStatus string
+
+func (p *PostStatus) UnmarshalJSON(data []byte) error {
+	var status string
+	err := json.Unmarshal(data, &status)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case "draft", "published", "unknown":
+		*p = PostStatus(status)
+		return nil
+	}
+
+	return fmt.Errorf("unknown post status %s", status)
+}
+
+type StatusPost struct {
+	ID          string     `json:"-"`
+	Status      PostStatus `jsonapi:"fallback=unknown"`
+	StrictState PostStatus
+}
+
+func (s *StatusPost) SetID(ID string) error {
+	s.ID = ID
+
+	return nil
+}
+
 type SQLNullPost struct {
 	ID     string `json:"-"`
 	Title  zero.String
@@ -363,6 +406,346 @@ func (r RenamedComment) GetName() string {
 	return "renamed-comments"
 }
 
+type LinkedPost struct {
+	ID    string `json:"-"`
+	Title string
+}
+
+func (l LinkedPost) GetID() string {
+	return l.ID
+}
+
+func (l LinkedPost) SelfLink() string {
+	if l.ID == "" {
+		return ""
+	}
+
+	return "http://my.domain/v1/linkedPosts/" + l.ID
+}
+
+type RenamedPost struct {
+	ID    string `json:"-"`
+	Title string
+}
+
+func (r *RenamedPost) SetID(ID string) error {
+	r.ID = ID
+
+	return nil
+}
+
+func (r *RenamedPost) GetName() string {
+	return "renamed-posts"
+}
+
+type Like struct {
+	ID      string `json:"-"`
+	PostID  string `json:"-"`
+	PhotoID string `json:"-"`
+}
+
+func (l *Like) SetID(ID string) error {
+	l.ID = ID
+
+	return nil
+}
+
+func (l *Like) SetToOneReferenceID(name, ID string) error {
+	return errors.New("There is no untyped to-one relationship named " + name)
+}
+
+func (l *Like) SetTypedToOneReferenceID(name, typ, ID string) error {
+	if name == "parent" {
+		switch typ {
+		case "posts":
+			l.PostID = ID
+			return nil
+		case "photos":
+			l.PhotoID = ID
+			return nil
+		}
+
+		return errors.New("A like cannot belong to " + typ)
+	}
+
+	return errors.New("There is no to-one relationship named " + name)
+}
+
+type Draft struct {
+	ID        string `json:"-"`
+	Title     string
+	EditorID  string   `json:"-"`
+	ReviewIDs []string `json:"-"`
+}
+
+func (d Draft) GetID() string {
+	return d.ID
+}
+
+func (d Draft) GetReferences() []Reference {
+	return []Reference{
+		{
+			Type:      "users",
+			Name:      "editor",
+			OmitEmpty: true,
+		},
+		{
+			Type:      "reviews",
+			Name:      "reviews",
+			OmitEmpty: true,
+		},
+	}
+}
+
+func (d Draft) GetReferencedIDs() []ReferenceID {
+	result := []ReferenceID{}
+
+	if d.EditorID != "" {
+		result = append(result, ReferenceID{ID: d.EditorID, Name: "editor", Type: "users"})
+	}
+
+	for _, reviewID := range d.ReviewIDs {
+		result = append(result, ReferenceID{ID: reviewID, Name: "reviews", Type: "reviews"})
+	}
+
+	return result
+}
+
+type LegacyPost struct {
+	ID    string `json:"-"`
+	Title string
+	Body  string
+}
+
+func (l *LegacyPost) SetID(ID string) error {
+	l.ID = ID
+
+	return nil
+}
+
+func (l *LegacyPost) ResolveField(key string) ([]int, bool) {
+	switch key {
+	case "hdr":
+		return []int{1}, true
+	case "title":
+		return []int{2}, true
+	case "broken":
+		return []int{5}, true
+	}
+
+	return nil, false
+}
+
+type Account struct {
+	ID    string `json:"-"`
+	Name  string
+	Token string
+}
+
+func (a Account) GetID() string {
+	return a.ID
+}
+
+func (a Account) TransformAttributes(attributes map[string]interface{}) map[string]interface{} {
+	attributes["token"] = "[redacted]"
+
+	return attributes
+}
+
+type Review struct {
+	ID       string   `json:"-"`
+	AuthorID string   `json:"-"`
+	TagIDs   []string `json:"-"`
+}
+
+func (r *Review) SetID(ID string) error {
+	r.ID = ID
+
+	return nil
+}
+
+func (r Review) GetReferences() []Reference {
+	return []Reference{
+		{
+			Type:           "users",
+			Name:           "author",
+			StrictType:     true,
+			RejectEmptyIDs: true,
+		},
+		{
+			Type:           "tags",
+			Name:           "tags",
+			StrictType:     true,
+			Unique:         true,
+			RejectEmptyIDs: true,
+		},
+	}
+}
+
+func (r *Review) SetToOneReferenceID(name, ID string) error {
+	if name == "author" {
+		r.AuthorID = ID
+		return nil
+	}
+
+	return errors.New("There is no to-one relationship named " + name)
+}
+
+func (r *Review) SetToManyReferenceIDs(name string, IDs []string) error {
+	if name == "tags" {
+		r.TagIDs = IDs
+		return nil
+	}
+
+	return errors.New("There is no to-many relationship named " + name)
+}
+
+type VersionedPost struct {
+	ID         int    `json:"-"`
+	OriginalID string `jsonapi:"originalid"`
+	Title      string
+}
+
+func (v VersionedPost) GetID() string {
+	return strconv.Itoa(v.ID)
+}
+
+func (v *VersionedPost) SetID(stringID string) error {
+	id, err := strconv.Atoi(stringID)
+	if err != nil {
+		return err
+	}
+
+	v.ID = id
+
+	return nil
+}
+
+type Profile struct {
+	ID       string `json:"-"`
+	Nickname *string
+	Website  *string `jsonapi:"omitempty"`
+	Bio      string  `jsonapi:"omitempty"`
+}
+
+func (p Profile) GetID() string {
+	return p.ID
+}
+
+type Chapter struct {
+	ID       string   `json:"-"`
+	BookID   string   `json:"-"`
+	BookLID  string   `json:"-"`
+	PageIDs  []string `json:"-"`
+	PageLIDs []string `json:"-"`
+}
+
+func (c *Chapter) SetID(ID string) error {
+	c.ID = ID
+
+	return nil
+}
+
+func (c Chapter) GetReferences() []Reference {
+	return []Reference{
+		{
+			Type:       "books",
+			Name:       "book",
+			StrictType: true,
+		},
+		{
+			Type:       "pages",
+			Name:       "pages",
+			StrictType: true,
+			Unique:     true,
+		},
+	}
+}
+
+func (c *Chapter) SetToOneReferenceID(name, ID string) error {
+	if name == "book" {
+		c.BookID = ID
+		return nil
+	}
+
+	return errors.New("There is no to-one relationship named " + name)
+}
+
+func (c *Chapter) SetToManyReferenceIDs(name string, IDs []string) error {
+	if name == "pages" {
+		c.PageIDs = IDs
+		return nil
+	}
+
+	return errors.New("There is no to-many relationship named " + name)
+}
+
+func (c *Chapter) SetToOneReferenceLID(name, LID string) error {
+	if name == "book" {
+		c.BookLID = LID
+		return nil
+	}
+
+	return errors.New("There is no to-one relationship named " + name)
+}
+
+func (c *Chapter) SetToManyReferenceLIDs(name string, LIDs []string) error {
+	if name == "pages" {
+		c.PageLIDs = LIDs
+		return nil
+	}
+
+	return errors.New("There is no to-many relationship named " + name)
+}
+
+type Photo struct {
+	ID    string `json:"-"`
+	Title string
+	Links map[string]string `jsonapi:"links"`
+}
+
+func (p Photo) GetID() string {
+	return p.ID
+}
+
+type LockedDocument struct {
+	ID       string `json:"-"`
+	Title    string
+	LockedBy string `jsonapi:"meta=lock.owner"`
+}
+
+func (d LockedDocument) GetID() string {
+	return d.ID
+}
+
+type HiddenLinksPhoto struct {
+	ID    string            `json:"-"`
+	links map[string]string `jsonapi:"links"`
+}
+
+func (p HiddenLinksPhoto) GetID() string {
+	return p.ID
+}
+
+type AuditedComment struct {
+	ID   string `json:"-"`
+	Text string
+	Raw  json.RawMessage `jsonapi:"raw"`
+}
+
+func (c AuditedComment) GetID() string {
+	return c.ID
+}
+
+type BrokenPhoto struct {
+	ID    string                 `json:"-"`
+	Links map[string]interface{} `jsonapi:"links"`
+}
+
+func (b BrokenPhoto) GetID() string {
+	return b.ID
+}
+
 type CompleteServerInformation struct{}
 
 const completePrefix = "http://my.domain/v1"
@@ -28,10 +28,22 @@ type ReferenceID struct {
 // generated. You should do this if there are some references, but you do not want to load them.
 // Otherwise, if IsNotLoaded is false and GetReferencedIDs() returns no IDs for this reference name, an
 // empty `data` field will be added which means that there are no references.
+// If OmitEmpty is set to true, a loaded reference without any IDs is left out of the relationships
+// object entirely.
+// If StrictType is set to true, unmarshalling a relationship with this name fails if one of its
+// resource identifier objects has a type other than Type.
+// If Unique is set to true, duplicate IDs of a to-many relationship are removed when unmarshalling,
+// keeping the order of their first occurrence.
+// If RejectEmptyIDs is set to true, unmarshalling a relationship with this name fails if one of its
+// resource identifier objects has an empty id.
 type Reference struct {
-	Type        string
-	Name        string
-	IsNotLoaded bool
+	Type           string
+	Name           string
+	IsNotLoaded    bool
+	OmitEmpty      bool
+	StrictType     bool
+	Unique         bool
+	RejectEmptyIDs bool
 }
 
 // MarshalReferences must be implemented if the struct to be serialized has relations. This must be done
@@ -55,6 +67,18 @@ type MarshalIncludedRelations interface {
 	GetReferencedStructs() []MarshalIdentifier
 }
 
+// MarshalAttributesTransformer can be optionally implemented to post-process the attributes of a struct
+// before they are added to the resource object, for example to redact values or to add computed ones
+type MarshalAttributesTransformer interface {
+	TransformAttributes(attributes map[string]interface{}) map[string]interface{}
+}
+
+// MarshalSelfLink can be optionally implemented to add a top-level `links` object with a `self` link
+// when marshalling a single struct
+type MarshalSelfLink interface {
+	SelfLink() string
+}
+
 // ServerInformation can be passed to MarshalWithURLs to generate the `self` and `related` urls inside `links`
 type ServerInformation interface {
 	GetBaseURL() string
@@ -84,6 +108,80 @@ func MarshalToJSONWithURLs(val interface{}, information ServerInformation) ([]by
 	return json.Marshal(result)
 }
 
+// MarshalResults marshals the results of an atomic operations request to json.
+// Every result must implement `MarshalIdentifier` and is placed inside the `data` member
+// of its entry in `atomic:results`. A nil result, for example of a remove operation, is
+// written as null.
+func MarshalResults(results []interface{}) ([]byte, error) {
+	atomicResults := []interface{}{}
+
+	for _, r := range results {
+		if r == nil {
+			atomicResults = append(atomicResults, nil)
+			continue
+		}
+
+		element, ok := r.(MarshalIdentifier)
+		if !ok {
+			return []byte{}, errors.New("all results must implement api2go.MarshalIdentifier")
+		}
+
+		refValue := reflect.ValueOf(element)
+		if refValue.Kind() == reflect.Ptr && refValue.IsNil() {
+			atomicResults = append(atomicResults, nil)
+			continue
+		}
+
+		content, err := marshalData(element, serverInformationNil)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		atomicResults = append(atomicResults, map[string]interface{}{"data": content})
+	}
+
+	return json.Marshal(map[string]interface{}{"atomic:results": atomicResults})
+}
+
+// MarshalIncludedOnly marshals a slice of structs to a json document that has all of them inside
+// `included` and an empty `data` array. This can be used to prime the cache of a client.
+func MarshalIncludedOnly(values interface{}) ([]byte, error) {
+	if values == nil || reflect.TypeOf(values).Kind() != reflect.Slice {
+		return []byte{}, errors.New("values must be a slice")
+	}
+
+	val := reflect.ValueOf(values)
+	referencedStructs := []MarshalIdentifier{}
+	for i := 0; i < val.Len(); i++ {
+		element, ok := val.Index(i).Interface().(MarshalIdentifier)
+		if !ok {
+			return []byte{}, errors.New("all elements within the slice must implement api2go.MarshalIdentifier")
+		}
+
+		// reduceDuplicates calls GetID, so nil pointers must be rejected before
+		elementValue := reflect.ValueOf(element)
+		if elementValue.Kind() == reflect.Ptr && elementValue.IsNil() {
+			return []byte{}, errors.New("MarshalIdentifier must not be nil")
+		}
+
+		referencedStructs = append(referencedStructs, element)
+	}
+
+	includedElements, err := reduceDuplicates(referencedStructs, serverInformationNil, marshalData)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if includedElements == nil {
+		includedElements = []map[string]interface{}{}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"data":     []interface{}{},
+		"included": includedElements,
+	})
+}
+
 // MarshalWithURLs can be used to include the generation of `related` and `self` links
 func MarshalWithURLs(data interface{}, information ServerInformation) (map[string]interface{}, error) {
 	return marshal(data, information)
@@ -212,9 +310,22 @@ func marshalData(element MarshalIdentifier, information ServerInformation) (map[
 		attributes[k] = v
 	}
 
+	if transformer, ok := element.(MarshalAttributesTransformer); ok {
+		result["attributes"] = transformer.TransformAttributes(attributes)
+	}
+
 	result["id"] = id
 	result["type"] = getStructType(element)
 
+	links, err := getResourceLinks(element)
+	if err != nil {
+		return result, err
+	}
+
+	if len(links) > 0 {
+		result["links"] = links
+	}
+
 	// optional relationship interface for struct
 	references, ok := element.(MarshalLinkedRelations)
 	if ok {
@@ -279,6 +390,10 @@ func getStructRelationships(relationer MarshalLinkedRelations, information Serve
 
 	// check for empty references
 	for name, reference := range notIncludedReferences {
+		if reference.OmitEmpty && !reference.IsNotLoaded {
+			continue
+		}
+
 		relationships[name] = map[string]interface{}{}
 		// Plural empty relationships need an empty array and empty to-one need a null in the json
 		if !reference.IsNotLoaded {
@@ -350,6 +465,12 @@ func marshalStruct(data MarshalIdentifier, information ServerInformation) (map[s
 
 	result["data"] = contentData
 
+	if selfLinker, ok := data.(MarshalSelfLink); ok {
+		if selfLink := selfLinker.SelfLink(); selfLink != "" {
+			result["links"] = map[string]string{"self": selfLink}
+		}
+	}
+
 	included, ok := data.(MarshalIncludedRelations)
 	if ok {
 		included, err := getIncludedStructs(included, information)
@@ -400,13 +521,78 @@ func getStructFields(data MarshalIdentifier) map[string]interface{} {
 			continue
 		}
 
+		// links are added to the resource object, not to its attributes
+		if GetTagValueByName(valType.Field(i), "links") != "" {
+			continue
+		}
+
+		// the raw resource is only filled when unmarshalling
+		if GetTagValueByName(valType.Field(i), "raw") != "" {
+			continue
+		}
+
+		// values from the resource meta are no attributes
+		if GetTagValueByName(valType.Field(i), "meta") != "" {
+			continue
+		}
+
+		// the original id is only filled when unmarshalling
+		if GetTagValueByName(valType.Field(i), "originalid") != "" {
+			continue
+		}
+
 		name := GetTagValueByName(valType.Field(i), "name")
 		if name != "" {
 			keyName = name
 		}
 
+		// nil pointers are marshalled to null unless the field is tagged with `jsonapi:"omitempty"`
+		if GetTagValueByName(valType.Field(i), "omitempty") != "" && isNilValue(field) {
+			continue
+		}
+
 		result[keyName] = field.Interface()
 	}
 
 	return result
 }
+
+// getResourceLinks returns the content of a map[string]string field tagged with `links`,
+// for example `jsonapi:"links"`. Unexported tagged fields and fields of other types return an error.
+func getResourceLinks(data MarshalIdentifier) (map[string]string, error) {
+	val := reflect.ValueOf(data)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	for i := 0; i < val.NumField(); i++ {
+		if GetTagValueByName(val.Type().Field(i), "links") == "" {
+			continue
+		}
+
+		field := val.Field(i)
+		if !field.CanInterface() {
+			return nil, fmt.Errorf("links field %s must be an exported map[string]string", val.Type().Field(i).Name)
+		}
+
+		links, ok := field.Interface().(map[string]string)
+		if !ok {
+			return nil, fmt.Errorf("links field %s must be an exported map[string]string", val.Type().Field(i).Name)
+		}
+
+		return links, nil
+	}
+
+	return nil, nil
+}
+
+// isNilValue checks for nil pointers and interfaces. Other empty values like "" or 0 are kept
+// even with omitempty, because they are valid attribute values.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
+}
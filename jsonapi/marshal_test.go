@@ -120,6 +120,24 @@ var _ = Describe("Marshalling", func() {
 			}))
 		})
 
+		It("marshals nil collections", func() {
+			var posts []SimplePost
+			i, err := Marshal(posts)
+			Expect(err).To(BeNil())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": []map[string]interface{}{},
+			}))
+		})
+
+		It("marshals empty and nil collections to an empty data array in JSON", func() {
+			var nilPosts []SimplePost
+			for _, posts := range [][]SimplePost{nilPosts, []SimplePost{}} {
+				result, err := MarshalToJSON(posts)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(MatchJSON(`{"data": []}`))
+			}
+		})
+
 		It("marshals slices of interface with one struct", func() {
 			i, err := Marshal([]interface{}{firstPost})
 			Expect(err).ToNot(HaveOccurred())
@@ -474,6 +492,199 @@ var _ = Describe("Marshalling", func() {
 		})
 	})
 
+	Context("when marshalling raw fields", func() {
+		It("does not add them to the attributes", func() {
+			i, err := Marshal(AuditedComment{ID: "1", Text: "First", Raw: []byte(`{"type": "auditedComments"}`)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "auditedComments",
+					"attributes": map[string]interface{}{
+						"text": "First",
+					},
+				},
+			}))
+		})
+	})
+
+	Context("when marshalling original id fields", func() {
+		It("does not add them to the attributes", func() {
+			i, err := Marshal(VersionedPost{ID: 7, OriginalID: "6", Title: "Test"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "7",
+					"type": "versionedPosts",
+					"attributes": map[string]interface{}{
+						"title": "Test",
+					},
+				},
+			}))
+		})
+	})
+
+	Context("when marshalling meta fields", func() {
+		It("does not add them to the attributes", func() {
+			i, err := Marshal(LockedDocument{ID: "1", Title: "Test", LockedBy: "nino"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "lockedDocuments",
+					"attributes": map[string]interface{}{
+						"title": "Test",
+					},
+				},
+			}))
+		})
+	})
+
+	Context("when marshalling resource links", func() {
+		It("adds the links of the tagged field to the resource object", func() {
+			photo := Photo{ID: "1", Title: "Sunset", Links: map[string]string{
+				"self":     "http://my.domain/v1/photos/1",
+				"download": "http://cdn.my.domain/sunset.jpg",
+			}}
+			i, err := Marshal([]Photo{photo})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": []map[string]interface{}{
+					map[string]interface{}{
+						"id":   "1",
+						"type": "photos",
+						"attributes": map[string]interface{}{
+							"title": "Sunset",
+						},
+						"links": map[string]string{
+							"self":     "http://my.domain/v1/photos/1",
+							"download": "http://cdn.my.domain/sunset.jpg",
+						},
+					},
+				},
+			}))
+		})
+
+		It("errors on links fields of other types", func() {
+			_, err := Marshal(BrokenPhoto{ID: "1", Links: map[string]interface{}{"self": "http://my.domain/v1/photos/1"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("links field Links must be an exported map[string]string"))
+		})
+
+		It("errors on unexported links fields", func() {
+			_, err := Marshal(HiddenLinksPhoto{ID: "1", links: map[string]string{"self": "http://my.domain/v1/photos/1"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("links field links must be an exported map[string]string"))
+		})
+
+		It("skips empty links", func() {
+			i, err := Marshal(Photo{ID: "1", Title: "Sunset"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "photos",
+					"attributes": map[string]interface{}{
+						"title": "Sunset",
+					},
+				},
+			}))
+		})
+	})
+
+	Context("when marshalling nil pointers", func() {
+		It("marshals nil pointers to null unless they are tagged with omitempty", func() {
+			i, err := Marshal(Profile{ID: "1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "profiles",
+					"attributes": map[string]interface{}{
+						"nickname": (*string)(nil),
+						"bio":      "",
+					},
+				},
+			}))
+
+			result, err := MarshalToJSON(Profile{ID: "1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`{"data": {"id": "1", "type": "profiles", "attributes": {"nickname": null, "bio": ""}}}`))
+		})
+
+		It("marshals fields tagged with omitempty that are set", func() {
+			nickname := "nino"
+			website := "http://example.com"
+			result, err := MarshalToJSON(Profile{ID: "1", Nickname: &nickname, Website: &website, Bio: "Gopher"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`{"data": {"id": "1", "type": "profiles", "attributes": {"nickname": "nino", "website": "http://example.com", "bio": "Gopher"}}}`))
+		})
+	})
+
+	Context("when marshalling with an attributes transformer", func() {
+		It("uses the transformed attributes", func() {
+			i, err := Marshal(Account{ID: "1", Name: "Nino", Token: "secret"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "accounts",
+					"attributes": map[string]interface{}{
+						"name":  "Nino",
+						"token": "[redacted]",
+					},
+				},
+			}))
+		})
+	})
+
+	Context("when marshalling with relations that omit empty values", func() {
+		It("omits empty relations", func() {
+			i, err := Marshal(Draft{ID: "1", Title: "Test"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "drafts",
+					"attributes": map[string]interface{}{
+						"title": "Test",
+					},
+					"relationships": map[string]map[string]interface{}{},
+				},
+			}))
+		})
+
+		It("marshals relations that are not empty", func() {
+			i, err := Marshal(Draft{ID: "1", Title: "Test", EditorID: "2", ReviewIDs: []string{"3"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "drafts",
+					"attributes": map[string]interface{}{
+						"title": "Test",
+					},
+					"relationships": map[string]map[string]interface{}{
+						"editor": map[string]interface{}{
+							"data": map[string]interface{}{
+								"type": "users",
+								"id":   "2",
+							},
+						},
+						"reviews": map[string]interface{}{
+							"data": []map[string]interface{}{
+								{
+									"type": "reviews",
+									"id":   "3",
+								},
+							},
+						},
+					},
+				},
+			}))
+		})
+	})
+
 	Context("when marshalling zero value types", func() {
 		theFloat := zero.NewFloat(2.3, true)
 		post := ZeroPost{ID: "1", Title: "test", Value: theFloat}
@@ -836,6 +1047,138 @@ var _ = Describe("Marshalling", func() {
 		})
 	})
 
+	Context("when marshalling structs with a self link", func() {
+		It("adds the top-level self link", func() {
+			result, err := MarshalToJSON(LinkedPost{ID: "1", Title: "Linked"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`
+				{
+					"data": {
+						"id": "1",
+						"type": "linkedPosts",
+						"attributes": {
+							"title": "Linked"
+						}
+					},
+					"links": {
+						"self": "http://my.domain/v1/linkedPosts/1"
+					}
+				}
+			`))
+		})
+
+		It("skips empty self links", func() {
+			result, err := Marshal(LinkedPost{Title: "Linked"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(HaveKey("links"))
+		})
+
+		It("does not add self links to collections", func() {
+			result, err := Marshal([]LinkedPost{LinkedPost{ID: "1"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(HaveKey("links"))
+		})
+	})
+
+	Context("when marshalling atomic results", func() {
+		It("marshals resources and null results", func() {
+			var removedUser *User
+			result, err := MarshalResults([]interface{}{
+				User{ID: 1, Name: "Nino"},
+				nil,
+				Comment{ID: 2, Text: "Second!"},
+				removedUser,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`
+				{
+					"atomic:results": [
+						{
+							"data": {
+								"id": "1",
+								"type": "users",
+								"attributes": {
+									"name": "Nino"
+								}
+							}
+						},
+						null,
+						{
+							"data": {
+								"id": "2",
+								"type": "comments",
+								"attributes": {
+									"text": "Second!"
+								}
+							}
+						},
+						null
+					]
+				}
+			`))
+		})
+
+		It("marshals empty results", func() {
+			result, err := MarshalResults([]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`{"atomic:results": []}`))
+		})
+
+		It("errors on results that are no MarshalIdentifier", func() {
+			_, err := MarshalResults([]interface{}{"invalid"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when marshalling included only documents", func() {
+		It("puts all structs into included", func() {
+			result, err := MarshalIncludedOnly([]interface{}{
+				User{ID: 1, Name: "Nino"},
+				Comment{ID: 2, Text: "Second!"},
+				User{ID: 1, Name: "Nino"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`
+				{
+					"data": [],
+					"included": [
+						{
+							"id": "1",
+							"type": "users",
+							"attributes": {
+								"name": "Nino"
+							}
+						},
+						{
+							"id": "2",
+							"type": "comments",
+							"attributes": {
+								"text": "Second!"
+							}
+						}
+					]
+				}
+			`))
+		})
+
+		It("marshals empty slices", func() {
+			result, err := MarshalIncludedOnly([]User{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`{"data": [], "included": []}`))
+		})
+
+		It("errors on values that are no slice", func() {
+			_, err := MarshalIncludedOnly(User{ID: 1})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("errors on nil pointers", func() {
+			_, err := MarshalIncludedOnly([]*User{nil})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("MarshalIdentifier must not be nil"))
+		})
+	})
+
 	// In order to use the SQL Null-Types the Marshal/Unmarshal interfaces for these types must be implemented.
 	// The library "gopkg.in/guregu/null.v2/zero" can be used for that.
 	Context("SQL Null-Types", func() {
@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -24,6 +28,30 @@ type UnmarshalToManyRelations interface {
 	SetToManyReferenceIDs(name string, IDs []string) error
 }
 
+// The UnmarshalTypedToOneRelations interface can be optionally implemented to route a to-one relationship
+// to different fields depending on the type of the related resource, e.g. a `parent` relationship that
+// points either to a post or to a photo. If it is implemented, it is used instead of SetToOneReferenceID
+// whenever the resource identifier object carries a type.
+type UnmarshalTypedToOneRelations interface {
+	SetTypedToOneReferenceID(name, typ, ID string) error
+}
+
+// The UnmarshalFieldResolver interface can be optionally implemented to map attribute keys to struct fields
+// for unusual schemas. If ResolveField returns true, the field with the returned index, as used by
+// reflect.Value.FieldByIndex, is set. Otherwise the field is looked up by name and jsonapi tags as usual.
+type UnmarshalFieldResolver interface {
+	ResolveField(key string) (fieldIndex []int, ok bool)
+}
+
+// The UnmarshalLocalIDRelations interface must be implemented to unmarshal relationships to resources
+// that do not exist yet. Such resource identifier objects have a local id `lid` instead of an `id`, as used
+// by the atomic operations extension to reference resources that are created in the same request.
+// Identifier objects with an id in the same to-many relationship are still passed to SetToManyReferenceIDs.
+type UnmarshalLocalIDRelations interface {
+	SetToOneReferenceLID(name, LID string) error
+	SetToManyReferenceLIDs(name string, LIDs []string) error
+}
+
 // The EditToManyRelations interface can be optionally implemented to add and delete to-many
 // relationships on a already unmarshalled struct. These methods are used by our API for the to-many
 // relationship update routes.
@@ -77,6 +105,49 @@ type EditToManyRelations interface {
 // Unmarshal reads a JSONAPI map to a model struct
 // target must at least implement the `UnmarshalIdentifier` interface.
 func Unmarshal(input map[string]interface{}, target interface{}) error {
+	_, err := unmarshal(input, target)
+	return err
+}
+
+// UnmarshalSplit works like `Unmarshal` and additionally splits the unmarshalled models into the ones
+// that were created, because they had no id in the payload, and the ones that were updated.
+// The returned values are the elements of target, so they can be used to route inserts and updates.
+func UnmarshalSplit(input map[string]interface{}, target interface{}) (created, updated []reflect.Value, err error) {
+	models, ok := input["data"].([]interface{})
+	if !ok {
+		models = []interface{}{input["data"]}
+	}
+
+	// a struct only holds the first model, so the others could not be returned
+	targetPtr := reflect.ValueOf(target)
+	if len(models) > 1 && targetPtr.Kind() == reflect.Ptr && targetPtr.Elem().Kind() != reflect.Slice {
+		return nil, nil, errors.New("a struct target can only be split with a single model, use a slice for multiple models")
+	}
+
+	indices, err := unmarshal(input, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetVal := reflect.ValueOf(target).Elem()
+	for i, m := range models {
+		element := targetVal
+		if targetVal.Kind() == reflect.Slice {
+			element = targetVal.Index(indices[i])
+		}
+
+		if m.(map[string]interface{})["id"] == nil {
+			created = append(created, element)
+		} else {
+			updated = append(updated, element)
+		}
+	}
+
+	return created, updated, nil
+}
+
+// unmarshal sets the models of input into target and returns the index in target for every model
+func unmarshal(input map[string]interface{}, target interface{}) ([]int, error) {
 	var (
 		structType reflect.Type
 		sliceVal   reflect.Value
@@ -88,7 +159,7 @@ func Unmarshal(input map[string]interface{}, target interface{}) error {
 	// Check that target is a *[]Model
 	ptrVal := reflect.ValueOf(target)
 	if ptrVal.Kind() != reflect.Ptr || ptrVal.IsNil() {
-		return typeError
+		return nil, typeError
 	}
 	targetType := reflect.TypeOf(target).Elem()
 
@@ -99,7 +170,7 @@ func Unmarshal(input map[string]interface{}, target interface{}) error {
 		} else if targetType.Kind() == reflect.Ptr {
 			structType = targetType.Elem()
 		} else {
-			return typeError
+			return nil, typeError
 		}
 		sliceVal = reflect.New(reflect.SliceOf(targetType)).Elem()
 		isStruct = true
@@ -112,15 +183,16 @@ func Unmarshal(input map[string]interface{}, target interface{}) error {
 	}
 
 	if structType.Kind() != reflect.Struct {
-		return typeError
+		return nil, typeError
 	}
 
 	// Copy the value, then write into the new variable.
 	// Later Set() the actual value of the pointee.
 	val := sliceVal
-	err := UnmarshalInto(input, structType, &val)
+	indices := []int{}
+	err := unmarshalInto(input, structType, &val, &indices)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// if target is a struct, the first unmarshalled entry of a slice of its type will be set into it
@@ -129,7 +201,27 @@ func Unmarshal(input map[string]interface{}, target interface{}) error {
 	} else {
 		sliceVal.Set(val)
 	}
-	return nil
+	return indices, nil
+}
+
+// UnmarshalIntoField works like `Unmarshal` but unmarshals into the field `fieldName` of the struct that
+// `wrapper` points to, for example the items of a page struct that is not a resource itself.
+func UnmarshalIntoField(input map[string]interface{}, wrapper interface{}, fieldName string) error {
+	wrapperVal := reflect.ValueOf(wrapper)
+	if wrapperVal.Kind() != reflect.Ptr || wrapperVal.Elem().Kind() != reflect.Struct {
+		return errors.New("wrapper must be a pointer to a struct")
+	}
+
+	field := wrapperVal.Elem().FieldByName(fieldName)
+	if !field.IsValid() {
+		return fmt.Errorf("expected struct %s to have field %s", wrapperVal.Elem().Type().Name(), fieldName)
+	}
+
+	if !field.CanAddr() || !field.CanSet() {
+		return fmt.Errorf("field %s must be exported", fieldName)
+	}
+
+	return Unmarshal(input, field.Addr().Interface())
 }
 
 // UnmarshalFromJSON reads a JSONAPI compatible JSON document to a model struct
@@ -143,9 +235,129 @@ func UnmarshalFromJSON(data []byte, target interface{}) error {
 	return Unmarshal(ctx, target)
 }
 
+// UnmarshalFromReader reads a JSONAPI compatible JSON document from `reader` to a model struct.
+// Like `UnmarshalFromJSON` it returns an error if there is more data after the document,
+// for example a second concatenated document.
+func UnmarshalFromReader(reader io.Reader, target interface{}) error {
+	var ctx map[string]interface{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&ctx)
+	if err != nil {
+		return err
+	}
+
+	var trailing interface{}
+	if decoder.Decode(&trailing) != io.EOF {
+		return errors.New("unexpected data after the JSON document")
+	}
+
+	return Unmarshal(ctx, target)
+}
+
+// UnmarshalFromReaderWithLimit works like `UnmarshalFromReader` but reads at most `maxBodyBytes` bytes
+// from `reader`. Larger documents are not decoded and an error is returned instead.
+func UnmarshalFromReaderWithLimit(reader io.Reader, target interface{}, maxBodyBytes int64) error {
+	if maxBodyBytes < 0 {
+		return errors.New("maxBodyBytes must not be negative")
+	}
+
+	// one more byte is read to detect documents that exceed the limit
+	readLimit := maxBodyBytes
+	if readLimit < math.MaxInt64 {
+		readLimit++
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(reader, readLimit))
+	if err != nil {
+		return err
+	}
+
+	if int64(len(data)) > maxBodyBytes {
+		return fmt.Errorf("JSON document exceeds the limit of %d bytes", maxBodyBytes)
+	}
+
+	return UnmarshalFromJSON(data, target)
+}
+
+// UnmarshalWithMeta works like `Unmarshal` and additionally stores the top-level `meta`
+// object of the document in `meta`. If the document has no meta, `meta` is left untouched.
+func UnmarshalWithMeta(input map[string]interface{}, target interface{}, meta *map[string]interface{}) error {
+	// meta is validated first, so that target is not changed for invalid documents
+	documentMeta, ok := input["meta"].(map[string]interface{})
+	if !ok && input["meta"] != nil {
+		return errors.New("expected meta to be an object")
+	}
+
+	err := Unmarshal(input, target)
+	if err != nil {
+		return err
+	}
+
+	if meta == nil || documentMeta == nil {
+		return nil
+	}
+
+	*meta = documentMeta
+
+	return nil
+}
+
+// UnmarshalFromJSONWithMeta reads a JSONAPI compatible JSON document like `UnmarshalFromJSON`
+// and stores its top-level `meta` object in `meta`
+func UnmarshalFromJSONWithMeta(data []byte, target interface{}, meta *map[string]interface{}) error {
+	var ctx map[string]interface{}
+	err := json.Unmarshal(data, &ctx)
+	if err != nil {
+		return err
+	}
+	return UnmarshalWithMeta(ctx, target, meta)
+}
+
+// UnmarshalWithMask works like `Unmarshal` and additionally returns a mask for every model in `data`.
+// The mask contains the names of all attributes and relationships that were present in the payload,
+// in the same order as the models in the document. This is useful to only update the fields of a
+// PATCH request that the client sent.
+func UnmarshalWithMask(input map[string]interface{}, target interface{}) ([]map[string]bool, error) {
+	err := Unmarshal(input, target)
+	if err != nil {
+		return nil, err
+	}
+
+	models, ok := input["data"].([]interface{})
+	if !ok {
+		models = []interface{}{input["data"]}
+	}
+
+	masks := make([]map[string]bool, 0, len(models))
+	for _, m := range models {
+		mask := map[string]bool{}
+		data := m.(map[string]interface{})
+
+		attributes, _ := data["attributes"].(map[string]interface{})
+		for key := range attributes {
+			mask[key] = true
+		}
+
+		relationships, _ := data["relationships"].(map[string]interface{})
+		for name := range relationships {
+			mask[name] = true
+		}
+
+		masks = append(masks, mask)
+	}
+
+	return masks, nil
+}
+
 // UnmarshalInto reads input params for one struct from `input` and marshals it into `targetSliceVal`,
 // which may be a slice of targetStructType or a slice of pointers to targetStructType.
 func UnmarshalInto(input map[string]interface{}, targetStructType reflect.Type, targetSliceVal *reflect.Value) error {
+	return unmarshalInto(input, targetStructType, targetSliceVal, nil)
+}
+
+// unmarshalInto works like `UnmarshalInto` and appends the index of every model in targetSliceVal to
+// indices, if it is not nil
+func unmarshalInto(input map[string]interface{}, targetStructType reflect.Type, targetSliceVal *reflect.Value, indices *[]int) error {
 	// Read models slice
 	var modelsInterface interface{}
 
@@ -167,6 +379,7 @@ func UnmarshalInto(input map[string]interface{}, targetStructType reflect.Type,
 
 		var val reflect.Value
 		isNew := true
+		index := targetSliceVal.Len()
 		id := ""
 
 		if v := data["id"]; v != nil {
@@ -190,6 +403,7 @@ func UnmarshalInto(input map[string]interface{}, targetStructType reflect.Type,
 						val = obj.Elem()
 					}
 					isNew = false
+					index = i
 					break
 				}
 			}
@@ -226,7 +440,10 @@ func UnmarshalInto(input map[string]interface{}, targetStructType reflect.Type,
 					return errors.New("expected id to be of type string")
 				}
 
-				targetStruct.SetID(id)
+				// SetID can validate the id, for example that it is numeric
+				if err := targetStruct.SetID(id); err != nil {
+					return err
+				}
 
 			case "type":
 				var expectedType string
@@ -235,7 +452,13 @@ func UnmarshalInto(input map[string]interface{}, targetStructType reflect.Type,
 					return errors.New("type must be string")
 				}
 
-				entityName, ok := val.Interface().(EntityNamer)
+				// the pointer also covers GetName methods with a pointer receiver
+				namer := val.Interface()
+				if val.CanAddr() {
+					namer = val.Addr().Interface()
+				}
+
+				entityName, ok := namer.(EntityNamer)
 				if ok {
 					expectedType = entityName.GetName()
 				} else {
@@ -246,23 +469,66 @@ func UnmarshalInto(input map[string]interface{}, targetStructType reflect.Type,
 				}
 				// do not unmarshal the `type` field
 
+			case "meta":
+				meta, ok := v.(map[string]interface{})
+				if !ok {
+					return errors.New("expected meta to be an object")
+				}
+
+				if err := unmarshalResourceMeta(val, meta); err != nil {
+					return err
+				}
+
 			case "attributes":
 				attributes, ok := v.(map[string]interface{})
 				if !ok {
 					return errors.New("expected attributes to be an object")
 				}
 
+				var resolver UnmarshalFieldResolver
+				if val.CanAddr() {
+					resolver, _ = val.Addr().Interface().(UnmarshalFieldResolver)
+				}
+
 				for key, attributeValue := range attributes {
 					fieldName := Dejsonify(key)
 					field := val.FieldByName(fieldName)
+					structField, _ := val.Type().FieldByName(fieldName)
+					if resolver != nil {
+						if fieldIndex, ok := resolver.ResolveField(key); ok {
+							if !isValidFieldIndex(val.Type(), fieldIndex) {
+								return fmt.Errorf("field index %v resolved for attribute %s is invalid for struct %s", fieldIndex, key, targetStructType.Name())
+							}
+
+							field = val.FieldByIndex(fieldIndex)
+							structField = val.Type().FieldByIndex(fieldIndex)
+							fieldName = structField.Name
+						}
+					}
+
 					if !field.IsValid() {
-						//check if there is any field tag with the given name available
+						//check if there is any field tag with the given name available, tags that
+						//only differ in case are used if there is no exact match
+						matches := []int{}
 						for x := 0; x < val.NumField(); x++ {
-							tfield := val.Type().Field(x)
-							name := GetTagValueByName(tfield, "name")
-							if name == strings.ToLower(fieldName) {
-								field = val.Field(x)
+							name := GetTagValueByName(val.Type().Field(x), "name")
+							if name == key {
+								matches = []int{x}
+								break
 							}
+
+							if name != "" && strings.EqualFold(name, key) {
+								matches = append(matches, x)
+							}
+						}
+
+						if len(matches) > 1 {
+							return fmt.Errorf("attribute %s matches the tags of multiple fields of struct %s", key, targetStructType.Name())
+						}
+
+						if len(matches) == 1 {
+							field = val.Field(matches[0])
+							structField = val.Type().Field(matches[0])
 						}
 
 						if !field.IsValid() {
@@ -273,39 +539,74 @@ func UnmarshalInto(input map[string]interface{}, targetStructType reflect.Type,
 					value := reflect.ValueOf(attributeValue)
 
 					if value.IsValid() {
+						// fields tagged with `singleormany` also accept a lone value for a slice
+						if field.Kind() == reflect.Slice && GetTagValueByName(structField, "singleormany") != "" {
+							if _, ok := attributeValue.([]interface{}); !ok {
+								attributeValue = []interface{}{attributeValue}
+							}
+						}
+
 						plainValue := reflect.ValueOf(attributeValue)
 
 						switch field.Interface().(type) {
 						case time.Time:
-							t, err := time.Parse(time.RFC3339, plainValue.String())
+							timeString, ok := attributeValue.(string)
+							if !ok {
+								return newUnmarshalError(fieldName, field, structField, attributeValue, fmt.Errorf("expected RFC3339 time string, got '%v'", attributeValue))
+							}
+
+							t, err := time.Parse(time.RFC3339, timeString)
 							if err != nil {
-								return errors.New("expected RFC3339 time string, got '" + plainValue.String() + "'")
+								return newUnmarshalError(fieldName, field, structField, attributeValue, errors.New("expected RFC3339 time string, got '"+timeString+"'"))
 							}
 
 							field.Set(reflect.ValueOf(t))
+						case json.Number:
+							// numbers are float64 by default and already json.Number if the
+							// input was decoded with UseNumber
+							switch number := attributeValue.(type) {
+							case json.Number:
+								field.Set(reflect.ValueOf(number))
+							case float64:
+								field.Set(reflect.ValueOf(json.Number(strconv.FormatFloat(number, 'f', -1, 64))))
+							default:
+								return newUnmarshalError(fieldName, field, structField, attributeValue, fmt.Errorf("Value '%v' had wrong type", attributeValue))
+							}
 						default:
 							if field.CanAddr() {
 								switch field.Addr().Interface().(type) {
 								default:
 									err := setFieldValue(&field, plainValue)
-									if err != nil {
-										return fmt.Errorf("Could not set field '%s'. %s", fieldName, err.Error())
+									if err != nil && !setFallbackValue(&field, structField, attributeValue) {
+										return newUnmarshalError(fieldName, field, structField, attributeValue, err)
 									}
 
 								}
 							} else {
 								err := setFieldValue(&field, plainValue)
-								if err != nil {
-									return fmt.Errorf("Could not set field '%s'. %s", fieldName, err.Error())
+								if err != nil && !setFallbackValue(&field, structField, attributeValue) {
+									return newUnmarshalError(fieldName, field, structField, attributeValue, err)
 								}
 							}
 						}
+
+						if err := checkFieldRange(field, structField); err != nil {
+							return newUnmarshalError(fieldName, field, structField, attributeValue, err)
+						}
 					}
 
 				}
 			}
 		}
 
+		if err := setOriginalID(val, id); err != nil {
+			return err
+		}
+
+		if err := setRawResource(val, data); err != nil {
+			return err
+		}
+
 		if isNew {
 			if targetSliceVal.Type().Elem().Kind() == reflect.Struct {
 				*targetSliceVal = reflect.Append(*targetSliceVal, val)
@@ -313,11 +614,66 @@ func UnmarshalInto(input map[string]interface{}, targetStructType reflect.Type,
 				*targetSliceVal = reflect.Append(*targetSliceVal, val.Addr())
 			}
 		}
+
+		if indices != nil {
+			*indices = append(*indices, index)
+		}
 	}
 
 	return nil
 }
 
+// UnmarshalError is returned if an attribute value could not be set into its struct field.
+// ExpectedKind is the kind of the field and ActualType the JSON type of the value, which is one of
+// string, number, boolean, array, object or null. Err contains the reason.
+// Message is the `msg` setting of the field's jsonapi tag, for example
+// `jsonapi:"msg=Please provide a valid email"`. If it is set, it replaces the reason in Error().
+type UnmarshalError struct {
+	Field        string
+	ExpectedKind reflect.Kind
+	ActualType   string
+	Err          error
+	Message      string
+}
+
+func (e UnmarshalError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("Could not set field '%s'. %s", e.Field, e.Message)
+	}
+
+	return fmt.Sprintf("Could not set field '%s'. %s", e.Field, e.Err.Error())
+}
+
+func newUnmarshalError(fieldName string, field reflect.Value, structField reflect.StructField, value interface{}, err error) UnmarshalError {
+	return UnmarshalError{
+		Field:        fieldName,
+		ExpectedKind: field.Kind(),
+		ActualType:   jsonTypeName(value),
+		Err:          err,
+		Message:      GetTagValueByName(structField, "msg"),
+	}
+}
+
+// jsonTypeName returns the name of the JSON type of a value from encoding/json
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
+
 // setFieldValue in a json object, there is only the number type, which defaults to float64. This method convertes float64 to the value
 // of the underlying struct field, for example uint64, or int32 etc...
 // If the field type is not one of the integers, it just sets the value
@@ -348,6 +704,16 @@ func setFieldValue(field *reflect.Value, value reflect.Value) (err error) {
 				return err
 			}
 		default:
+			// json arrays are []interface{}, so slices of other types are converted with encoding/json
+			if field.Kind() == reflect.Slice && !value.Type().AssignableTo(field.Type()) {
+				marshaledValue, err := json.Marshal(value.Interface())
+				if err != nil {
+					return err
+				}
+
+				return json.Unmarshal(marshaledValue, field.Addr().Interface())
+			}
+
 			field.Set(value)
 		}
 	}
@@ -355,6 +721,175 @@ func setFieldValue(field *reflect.Value, value reflect.Value) (err error) {
 	return nil
 }
 
+// setFallbackValue sets the `fallback` setting of the field's jsonapi tag, for example
+// `jsonapi:"fallback=unknown"`. It is used for strings that could not be set, like unknown enum values
+// that are rejected by the json.Unmarshaler of the field. It returns false if there is no fallback or
+// the value is not a string, so that values of a wrong type are still reported.
+func setFallbackValue(field *reflect.Value, structField reflect.StructField, value interface{}) bool {
+	if _, ok := value.(string); !ok {
+		return false
+	}
+
+	fallback := GetTagValueByName(structField, "fallback")
+	if fallback == "" {
+		return false
+	}
+
+	return setFieldValue(field, reflect.ValueOf(fallback)) == nil
+}
+
+// isValidFieldIndex checks that FieldByIndex can be called with fieldIndex without a panic
+func isValidFieldIndex(structType reflect.Type, fieldIndex []int) bool {
+	if len(fieldIndex) == 0 {
+		return false
+	}
+
+	for i, x := range fieldIndex {
+		if i > 0 {
+			structType = structType.Field(fieldIndex[i-1]).Type
+		}
+
+		if structType.Kind() != reflect.Struct || x < 0 || x >= structType.NumField() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setOriginalID sets the string fields tagged with `originalid` to the id of the payload, e.g. to
+// detect id changes. The fields are checked even if the payload has no id.
+func setOriginalID(val reflect.Value, id string) error {
+	for x := 0; x < val.NumField(); x++ {
+		structField := val.Type().Field(x)
+		if GetTagValueByName(structField, "originalid") == "" {
+			continue
+		}
+
+		field := val.Field(x)
+		if field.Kind() != reflect.String || !field.CanSet() {
+			return fmt.Errorf("originalid field %s must be an exported string", structField.Name)
+		}
+
+		if id != "" {
+			field.SetString(id)
+		}
+	}
+
+	return nil
+}
+
+// setRawResource sets the json.RawMessage fields tagged with `raw` to the JSON of the whole resource
+// object, for example to store the original representation for auditing
+func setRawResource(val reflect.Value, data map[string]interface{}) error {
+	for x := 0; x < val.NumField(); x++ {
+		structField := val.Type().Field(x)
+		if GetTagValueByName(structField, "raw") == "" {
+			continue
+		}
+
+		if structField.Type != reflect.TypeOf(json.RawMessage{}) || !val.Field(x).CanSet() {
+			return fmt.Errorf("raw field %s must be an exported json.RawMessage", structField.Name)
+		}
+
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		val.Field(x).Set(reflect.ValueOf(json.RawMessage(raw)))
+	}
+
+	return nil
+}
+
+// unmarshalResourceMeta sets the fields with a `meta` setting in their jsonapi tag to the value at that
+// path in the meta object of a resource. Nested values use a dotted path like `jsonapi:"meta=lock.version"`.
+func unmarshalResourceMeta(val reflect.Value, meta map[string]interface{}) error {
+	for x := 0; x < val.NumField(); x++ {
+		structField := val.Type().Field(x)
+		path := GetTagValueByName(structField, "meta")
+		if path == "" {
+			continue
+		}
+
+		field := val.Field(x)
+		if !field.CanSet() {
+			return fmt.Errorf("meta field %s must be exported", structField.Name)
+		}
+
+		var value interface{} = meta
+		for _, key := range strings.Split(path, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = object[key]
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if err := setFieldValue(&field, reflect.ValueOf(value)); err != nil {
+			return newUnmarshalError(structField.Name, field, structField, value, err)
+		}
+	}
+
+	return nil
+}
+
+// checkFieldRange validates the `min` and `max` settings of the field's jsonapi tag, for example
+// `jsonapi:"min=0;max=150"`. Numbers are compared by value, strings, slices and maps by length.
+func checkFieldRange(field reflect.Value, structField reflect.StructField) error {
+	for _, limit := range []string{"min", "max"} {
+		setting := GetTagValueByName(structField, limit)
+		if setting == "" {
+			continue
+		}
+
+		bound, err := strconv.ParseFloat(setting, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s setting '%s'", limit, setting)
+		}
+
+		subject := "Value"
+		var actual float64
+		// json.Number is a string type, but it is compared by value
+		if number, ok := field.Interface().(json.Number); ok {
+			actual, err = number.Float64()
+			if err != nil {
+				return err
+			}
+		} else {
+			switch field.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				actual = float64(field.Int())
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				actual = float64(field.Uint())
+			case reflect.Float32, reflect.Float64:
+				actual = field.Float()
+			case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+				subject = "Length"
+				actual = float64(field.Len())
+			default:
+				continue
+			}
+		}
+
+		if limit == "min" && actual < bound {
+			return fmt.Errorf("%s %v is less than the minimum of %s", subject, actual, setting)
+		}
+
+		if limit == "max" && actual > bound {
+			return fmt.Errorf("%s %v is greater than the maximum of %s", subject, actual, setting)
+		}
+	}
+
+	return nil
+}
+
 // UnmarshalRelationshipsData is used by api2go.API to only unmarshal references inside a data object.
 // The target interface must implement UnmarshalToOneRelations or UnmarshalToManyRelations interface.
 // The linksMap is the content of the data object from the json
@@ -386,20 +921,122 @@ func unmarshalRelationships(val reflect.Value, relationshipsMap map[string]inter
 	return nil
 }
 
+// getReference returns the reference with the given name if the target implements MarshalReferences,
+// and the zero value otherwise
+func getReference(target interface{}, linkName string) Reference {
+	references, ok := target.(MarshalReferences)
+	if !ok {
+		return Reference{}
+	}
+
+	for _, reference := range references.GetReferences() {
+		if reference.Name == linkName {
+			return reference
+		}
+	}
+
+	return Reference{}
+}
+
+// checkRelationshipType returns an error if the reference has StrictType set and the resource
+// identifier object has a different type
+func checkRelationshipType(identifier map[string]interface{}, linkName string, reference Reference) error {
+	identifierType, ok := identifier["type"].(string)
+	if !ok {
+		return nil
+	}
+
+	if reference.StrictType && reference.Type != identifierType {
+		return fmt.Errorf("type %s does not match expected type %s of relationship %s", identifierType, reference.Type, linkName)
+	}
+
+	return nil
+}
+
+// uniqueIDs removes duplicate ids and keeps the first occurrence of each id
+func uniqueIDs(IDs []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, ID := range IDs {
+		if !seen[ID] {
+			seen[ID] = true
+			result = append(result, ID)
+		}
+	}
+
+	return result
+}
+
+// relationshipID returns the id of a resource identifier object. Numeric ids, which some backends send
+// although JSON API requires strings, are converted to their string form.
+func relationshipID(id interface{}) (string, bool) {
+	switch id := id.(type) {
+	case string:
+		return id, true
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64), true
+	case json.Number:
+		return id.String(), true
+	}
+
+	return "", false
+}
+
 func processRelationshipsData(data interface{}, linkName string, target interface{}) error {
+	return setRelationshipData(data, linkName, target, getReference(target, linkName))
+}
+
+// setRelationshipData passes the ids of a relationship to the setters of the target, the reference
+// holds the options of the relationship and is the zero value if the target declares none
+func setRelationshipData(data interface{}, linkName string, target interface{}, reference Reference) error {
 	hasOne, ok := data.(map[string]interface{})
 	if ok {
-		hasOneID, ok := hasOne["id"].(string)
+		if hasOneLID, ok := localRelationshipID(hasOne); ok {
+			localTarget, ok := target.(UnmarshalLocalIDRelations)
+			if !ok {
+				return errors.New("target struct must implement interface UnmarshalLocalIDRelations")
+			}
+
+			if err := checkRelationshipType(hasOne, linkName, reference); err != nil {
+				return err
+			}
+
+			return localTarget.SetToOneReferenceLID(linkName, hasOneLID)
+		}
+
+		hasOneID, ok := relationshipID(hasOne["id"])
 		if !ok {
 			return fmt.Errorf("data object must have a field id for %s", linkName)
 		}
 
+		if reference.RejectEmptyIDs && hasOneID == "" {
+			return fmt.Errorf("data object must not have an empty id for %s, use null to remove the relationship", linkName)
+		}
+
+		if err := checkRelationshipType(hasOne, linkName, reference); err != nil {
+			return err
+		}
+
+		if typedTarget, ok := target.(UnmarshalTypedToOneRelations); ok {
+			if hasOneType, ok := hasOne["type"].(string); ok {
+				return typedTarget.SetTypedToOneReferenceID(linkName, hasOneType, hasOneID)
+			}
+
+			// the untyped setter decides about identifiers without a type
+			untypedTarget, ok := target.(UnmarshalToOneRelations)
+			if !ok {
+				return errors.New("target struct must implement interface UnmarshalToOneRelations")
+			}
+
+			return untypedTarget.SetToOneReferenceID(linkName, hasOneID)
+		}
+
 		target, ok := target.(UnmarshalToOneRelations)
 		if !ok {
 			return errors.New("target struct must implement interface UnmarshalToOneRelations")
 		}
 
-		target.SetToOneReferenceID(linkName, hasOneID)
+		return target.SetToOneReferenceID(linkName, hasOneID)
 	} else if data == nil {
 		// this means that a to-one relationship must be deleted
 		target, ok := target.(UnmarshalToOneRelations)
@@ -407,35 +1044,108 @@ func processRelationshipsData(data interface{}, linkName string, target interfac
 			return errors.New("target struct must implement interface UnmarshalToOneRelations")
 		}
 
-		target.SetToOneReferenceID(linkName, "")
+		return target.SetToOneReferenceID(linkName, "")
 	} else {
 		hasMany, ok := data.([]interface{})
 		if !ok {
 			return fmt.Errorf("invalid data object or array, must be an object with \"id\" and \"type\" field for %s", linkName)
 		}
 
+		// some clients send a to-one relationship as an array with a single resource identifier object
+		if _, ok := target.(UnmarshalToManyRelations); !ok {
+			if _, ok := target.(UnmarshalToOneRelations); ok {
+				if len(hasMany) != 1 {
+					return fmt.Errorf("to-one relationship %s must not have %d entries", linkName, len(hasMany))
+				}
+
+				entry, ok := hasMany[0].(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("entry in data array must be an object for %s", linkName)
+				}
+
+				return setRelationshipData(entry, linkName, target, reference)
+			}
+		}
+
 		target, ok := target.(UnmarshalToManyRelations)
 		if !ok {
 			return errors.New("target struct must implement interface UnmarshalToManyRelations")
 		}
 
 		hasManyIDs := []string{}
+		hasManyLIDs := []string{}
 
 		for _, entry := range hasMany {
 			data, ok := entry.(map[string]interface{})
 			if !ok {
 				return fmt.Errorf("entry in data array must be an object for %s", linkName)
 			}
-			dataID, ok := data["id"].(string)
+
+			if dataLID, ok := localRelationshipID(data); ok {
+				if err := checkRelationshipType(data, linkName, reference); err != nil {
+					return err
+				}
+
+				hasManyLIDs = append(hasManyLIDs, dataLID)
+				continue
+			}
+
+			dataID, ok := relationshipID(data["id"])
 			if !ok {
 				return fmt.Errorf("all data objects must have a field id for %s", linkName)
 			}
 
+			if reference.RejectEmptyIDs && dataID == "" {
+				return fmt.Errorf("data objects must not have an empty id for %s", linkName)
+			}
+
+			if err := checkRelationshipType(data, linkName, reference); err != nil {
+				return err
+			}
+
 			hasManyIDs = append(hasManyIDs, dataID)
 		}
 
-		target.SetToManyReferenceIDs(linkName, hasManyIDs)
+		if reference.Unique {
+			hasManyIDs = uniqueIDs(hasManyIDs)
+			hasManyLIDs = uniqueIDs(hasManyLIDs)
+		}
+
+		// check the interface before any setter is called, so that the ids are not replaced on errors
+		localTarget, ok := target.(UnmarshalLocalIDRelations)
+		if len(hasManyLIDs) > 0 && !ok {
+			return errors.New("target struct must implement interface UnmarshalLocalIDRelations")
+		}
+
+		if err := target.SetToManyReferenceIDs(linkName, hasManyIDs); err != nil {
+			// models with both kinds of relationships may get a to-one relationship as an array as well
+			if _, ok := target.(UnmarshalToOneRelations); ok && len(hasMany) == 1 && len(hasManyLIDs) == 0 {
+				if setRelationshipData(hasMany[0], linkName, target, reference) == nil {
+					return nil
+				}
+			}
+
+			return err
+		}
+
+		if len(hasManyLIDs) > 0 {
+			return localTarget.SetToManyReferenceLIDs(linkName, hasManyLIDs)
+		}
 	}
 
 	return nil
 }
+
+// localRelationshipID returns the `lid` of a resource identifier object without an id
+func localRelationshipID(identifier map[string]interface{}) (string, bool) {
+	if _, ok := identifier["id"]; ok {
+		return "", false
+	}
+
+	lid, ok := identifier["lid"].(string)
+	if !ok || lid == "" {
+		return "", false
+	}
+
+	return lid, true
+}
@@ -1,7 +1,13 @@
 package jsonapi
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
 	"time"
 
 	"gopkg.in/guregu/null.v2/zero"
@@ -139,6 +145,35 @@ var _ = Describe("Unmarshal", func() {
 			Expect(err.Error()).To(Equal("Could not set field 'Size'. Value 'blubb' had wrong type"))
 		})
 
+		It("returns the expected kind and actual type for wrong types", func() {
+			var posts []SimplePost
+			err := Unmarshal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"attributes": map[string]interface{}{
+						"title": true,
+					},
+				},
+			}, &posts)
+			Expect(err).To(HaveOccurred())
+			unmarshalError, ok := err.(UnmarshalError)
+			Expect(ok).To(BeTrue())
+			Expect(unmarshalError.Field).To(Equal("Title"))
+			Expect(unmarshalError.ExpectedKind).To(Equal(reflect.String))
+			Expect(unmarshalError.ActualType).To(Equal("boolean"))
+
+			err = Unmarshal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"attributes": map[string]interface{}{
+						"size": map[string]interface{}{},
+					},
+				},
+			}, &posts)
+			unmarshalError, ok = err.(UnmarshalError)
+			Expect(ok).To(BeTrue())
+			Expect(unmarshalError.ExpectedKind).To(Equal(reflect.Int))
+			Expect(unmarshalError.ActualType).To(Equal("object"))
+		})
+
 		It("errors with invalid time format", func() {
 			t, err := time.Parse(time.RFC3339, "2014-11-10T16:30:48.823Z")
 			faultyPostMap := map[string]interface{}{
@@ -155,7 +190,24 @@ var _ = Describe("Unmarshal", func() {
 			var posts []SimplePost
 			err = Unmarshal(faultyPostMap, &posts)
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("expected RFC3339 time string, got 'Mon, 10 Nov 2014 16:30:48 +0000'"))
+			Expect(err.Error()).To(Equal("Could not set field 'Created'. expected RFC3339 time string, got 'Mon, 10 Nov 2014 16:30:48 +0000'"))
+		})
+
+		It("returns an UnmarshalError for times of a wrong type", func() {
+			var posts []SimplePost
+			err := Unmarshal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"attributes": map[string]interface{}{
+						"created": float64(42),
+					},
+				},
+			}, &posts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Created'. expected RFC3339 time string, got '42'"))
+			unmarshalError, ok := err.(UnmarshalError)
+			Expect(ok).To(BeTrue())
+			Expect(unmarshalError.ExpectedKind).To(Equal(reflect.Struct))
+			Expect(unmarshalError.ActualType).To(Equal("number"))
 		})
 
 		It("unmarshals JSON", func() {
@@ -164,6 +216,101 @@ var _ = Describe("Unmarshal", func() {
 			Expect(err).To(BeNil())
 			Expect(posts).To(Equal([]SimplePost{firstPost}))
 		})
+
+		It("unmarshals JSON from a reader", func() {
+			var posts []SimplePost
+			err := UnmarshalFromReader(bytes.NewReader(singleJSON), &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]SimplePost{firstPost}))
+		})
+
+		It("allows trailing whitespace in a reader", func() {
+			var posts []SimplePost
+			err := UnmarshalFromReader(strings.NewReader(string(singleJSON)+"\n  \n"), &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]SimplePost{firstPost}))
+		})
+
+		It("errors on trailing data in a reader", func() {
+			var posts []SimplePost
+			err := UnmarshalFromReader(bytes.NewReader(append(singleJSON, singleJSON...)), &posts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("unexpected data after the JSON document"))
+
+			err = UnmarshalFromReader(strings.NewReader(string(singleJSON)+"garbage"), &posts)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("unmarshals JSON from a reader within a size limit", func() {
+			var posts []SimplePost
+			err := UnmarshalFromReaderWithLimit(bytes.NewReader(singleJSON), &posts, int64(len(singleJSON)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]SimplePost{firstPost}))
+		})
+
+		It("unmarshals JSON from a reader with the maximum size limit", func() {
+			var posts []SimplePost
+			err := UnmarshalFromReaderWithLimit(bytes.NewReader(singleJSON), &posts, math.MaxInt64)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]SimplePost{firstPost}))
+		})
+
+		It("errors on a negative size limit", func() {
+			var posts []SimplePost
+			err := UnmarshalFromReaderWithLimit(bytes.NewReader(singleJSON), &posts, -1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("maxBodyBytes must not be negative"))
+		})
+
+		It("errors on JSON from a reader over a size limit", func() {
+			var posts []SimplePost
+			err := UnmarshalFromReaderWithLimit(bytes.NewReader(singleJSON), &posts, int64(len(singleJSON)-1))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(fmt.Sprintf("JSON document exceeds the limit of %d bytes", len(singleJSON)-1)))
+			Expect(posts).To(BeEmpty())
+		})
+
+		It("unmarshals JSON with top-level meta", func() {
+			var (
+				posts []SimplePost
+				meta  map[string]interface{}
+			)
+			metaJSON := []byte(`{"meta": {"total": 1, "author": "Nino"}, "data":{"id": "1", "type": "simplePosts", "attributes": {"title":"First Post","text":"Lipsum", "Created": "2014-11-10T16:30:48.823Z"}}}`)
+			err := UnmarshalFromJSONWithMeta(metaJSON, &posts, &meta)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]SimplePost{firstPost}))
+			Expect(meta).To(Equal(map[string]interface{}{
+				"total":  float64(1),
+				"author": "Nino",
+			}))
+		})
+
+		It("leaves meta untouched without top-level meta", func() {
+			var posts []SimplePost
+			meta := map[string]interface{}{"untouched": true}
+			err := UnmarshalWithMeta(singlePostMap, &posts, &meta)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(HaveLen(1))
+			Expect(meta).To(Equal(map[string]interface{}{"untouched": true}))
+		})
+
+		It("errors on invalid top-level meta", func() {
+			var (
+				posts []SimplePost
+				meta  map[string]interface{}
+			)
+			err := UnmarshalFromJSONWithMeta([]byte(`{"meta": 42, "data":{"id": "1", "type": "simplePosts"}}`), &posts, &meta)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("leaves the target untouched on invalid top-level meta", func() {
+			var meta map[string]interface{}
+			posts := []SimplePost{SimplePost{ID: "1", Title: "Existing"}}
+			err := UnmarshalFromJSONWithMeta([]byte(`{"meta": 42, "data":{"id": "1", "type": "simplePosts", "attributes": {"title": "Changed"}}}`), &posts, &meta)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("expected meta to be an object"))
+			Expect(posts).To(Equal([]SimplePost{SimplePost{ID: "1", Title: "Existing"}}))
+		})
 	})
 
 	Context("when unmarshaling objects with relationships", func() {
@@ -263,6 +410,39 @@ var _ = Describe("Unmarshal", func() {
 			Expect(posts).To(Equal([]Post{post}))
 		})
 
+		It("unmarshals numeric relation ids", func() {
+			var posts []Post
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "3", "type": "posts", "attributes": {"title": "Test"}, "relationships": {"author": {"data": {"id": 1, "type": "users"}}, "comments": {"data": [{"id": 1, "type": "comments"}, {"id": 2, "type": "comments"}]}}}}`), &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]Post{Post{ID: 3, Title: "Test", AuthorID: sql.NullInt64{Valid: true, Int64: 1}, CommentsIDs: []int{1, 2}}}))
+
+			var review Review
+			err = UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "reviews", "relationships": {"tags": {"data": [{"id": 3, "type": "tags"}, {"id": 4, "type": "tags"}]}}}}`), &review)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(review).To(Equal(Review{ID: "1", TagIDs: []string{"3", "4"}}))
+		})
+
+		It("passes empty relation ids to the setters by default", func() {
+			var chapter Chapter
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "chapters", "relationships": {
+				"book": {"data": {"id": "", "type": "books"}},
+				"pages": {"data": [{"id": "2", "type": "pages"}, {"id": "", "type": "pages"}]}
+			}}}`), &chapter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chapter).To(Equal(Chapter{ID: "1", PageIDs: []string{"2", ""}}))
+		})
+
+		It("errors on empty relation ids for references with RejectEmptyIDs", func() {
+			var review Review
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "reviews", "relationships": {"author": {"data": {"id": "", "type": "users"}}}}}`), &review)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("data object must not have an empty id for author, use null to remove the relationship"))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "reviews", "relationships": {"tags": {"data": [{"id": "1", "type": "tags"}, {"id": "", "type": "tags"}]}}}}`), &review)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("data objects must not have an empty id for tags"))
+		})
+
 		It("unmarshal to-one and to-many relations", func() {
 			post := Post{ID: 3, Title: "Test", AuthorID: sql.NullInt64{Valid: true, Int64: 1}, Author: nil, CommentsIDs: []int{1, 2}}
 			postMap := map[string]interface{}{
@@ -302,6 +482,68 @@ var _ = Describe("Unmarshal", func() {
 			Expect(posts).To(Equal([]Post{post}))
 		})
 
+		It("unmarshals every relationship with its own shape", func() {
+			post := Post{ID: 3, AuthorID: sql.NullInt64{Valid: true, Int64: 1}, CommentsIDs: []int{1, 2}}
+			postMap := map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "3",
+					"type": "posts",
+					"relationships": map[string]interface{}{
+						"author": map[string]interface{}{
+							"data": map[string]interface{}{
+								"id":   "1",
+								"type": "users",
+							},
+						},
+						"comments": map[string]interface{}{
+							"data": []interface{}{
+								map[string]interface{}{"id": "1"},
+								map[string]interface{}{"id": "2"},
+							},
+						},
+					},
+				},
+			}
+			var posts []Post
+			err := Unmarshal(postMap, &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]Post{post}))
+		})
+
+		It("unmarshals linkage of resources that are not included", func() {
+			post := Post{ID: 1, Title: "Test", AuthorID: sql.NullInt64{Valid: true, Int64: 9}, Author: nil}
+			postMap := map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "posts",
+					"attributes": map[string]interface{}{
+						"title": "Test",
+					},
+					"relationships": map[string]interface{}{
+						"author": map[string]interface{}{
+							"data": map[string]interface{}{
+								"id":   "9",
+								"type": "users",
+							},
+						},
+					},
+				},
+				"included": []interface{}{
+					map[string]interface{}{
+						"id":   "1",
+						"type": "comments",
+						"attributes": map[string]interface{}{
+							"text": "First!",
+						},
+					},
+				},
+			}
+			var posts []Post
+			err := Unmarshal(postMap, &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]Post{post}))
+		})
+
 		It("unmarshal no linked content", func() {
 			post := Post{ID: 1, Title: "Test"}
 			postMap := map[string]interface{}{
@@ -335,6 +577,235 @@ var _ = Describe("Unmarshal", func() {
 			err := Unmarshal(postMap, &posts)
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("checks the type with a GetName pointer receiver", func() {
+			var posts []RenamedPost
+			err := UnmarshalFromJSON([]byte(`{"data": [{"id": "1", "type": "renamed-posts", "attributes": {"title": "Test"}}]}`), &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]RenamedPost{RenamedPost{ID: "1", Title: "Test"}}))
+
+			err = UnmarshalFromJSON([]byte(`{"data": [{"id": "1", "type": "renamedPosts"}]}`), &posts)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when unmarshaling polymorphic to-one relations", func() {
+		likeMap := func(parentType string) map[string]interface{} {
+			return map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "likes",
+					"relationships": map[string]interface{}{
+						"parent": map[string]interface{}{
+							"data": map[string]interface{}{
+								"id":   "2",
+								"type": parentType,
+							},
+						},
+					},
+				},
+			}
+		}
+
+		It("routes a post parent to the post id", func() {
+			var like Like
+			err := Unmarshal(likeMap("posts"), &like)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(like).To(Equal(Like{ID: "1", PostID: "2"}))
+		})
+
+		It("routes a photo parent to the photo id", func() {
+			var like Like
+			err := Unmarshal(likeMap("photos"), &like)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(like).To(Equal(Like{ID: "1", PhotoID: "2"}))
+		})
+
+		It("accepts an array with one entry for a to-one relation", func() {
+			var like Like
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "likes", "relationships": {"parent": {"data": [{"id": "2", "type": "photos"}]}}}}`), &like)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(like).To(Equal(Like{ID: "1", PhotoID: "2"}))
+		})
+
+		It("accepts an array with one entry for a to-one relation of a model with to-many relations", func() {
+			var post Post
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "posts", "relationships": {"author": {"data": [{"id": "9", "type": "users"}]}}}}`), &post)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(post.AuthorID).To(Equal(sql.NullInt64{Valid: true, Int64: 9}))
+		})
+
+		It("returns the to-many error if an array with one entry is no to-one relation either", func() {
+			var post Post
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "posts", "relationships": {"editor": {"data": [{"id": "9", "type": "users"}]}}}}`), &post)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("There is no to-many relationship named editor"))
+		})
+
+		It("errors on an array with one entry that is no object for a to-one relation", func() {
+			for _, data := range []string{`[[{"id": "2", "type": "photos"}]]`, `[null]`} {
+				like := Like{PhotoID: "3"}
+				err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "likes", "relationships": {"parent": {"data": `+data+`}}}}`), &like)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("entry in data array must be an object for parent"))
+			}
+		})
+
+		It("errors on an array with multiple entries for a to-one relation", func() {
+			var like Like
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "likes", "relationships": {"parent": {"data": [{"id": "2", "type": "photos"}, {"id": "3", "type": "photos"}]}}}}`), &like)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("to-one relationship parent must not have 2 entries"))
+		})
+
+		It("returns the error of the untyped setter for identifiers without a type", func() {
+			var like Like
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "likes", "relationships": {"parent": {"data": {"id": "2"}}}}}`), &like)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("There is no untyped to-one relationship named parent"))
+		})
+
+		It("returns the error of the setter for models without the typed interface", func() {
+			var review Review
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "reviews", "relationships": {"editor": {"data": {"id": "2", "type": "users"}}}}}`), &review)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("There is no to-one relationship named editor"))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "reviews", "relationships": {"editor": {"data": null}}}}`), &review)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("There is no to-one relationship named editor"))
+		})
+
+		It("returns the error of the typed setter", func() {
+			var like Like
+			err := Unmarshal(likeMap("comments"), &like)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("A like cannot belong to comments"))
+		})
+	})
+
+	Context("when unmarshaling relations with local ids", func() {
+		It("passes local ids to the local id setters", func() {
+			var chapter Chapter
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "chapters", "relationships": {
+				"book": {"data": {"lid": "new-book", "type": "books"}},
+				"pages": {"data": [{"id": "2", "type": "pages"}, {"lid": "new-page", "type": "pages"}]}
+			}}}`), &chapter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chapter).To(Equal(Chapter{ID: "1", BookLID: "new-book", PageIDs: []string{"2"}, PageLIDs: []string{"new-page"}}))
+		})
+
+		It("prefers ids over local ids", func() {
+			var chapter Chapter
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "chapters", "relationships": {
+				"book": {"data": {"id": "3", "lid": "new-book", "type": "books"}}
+			}}}`), &chapter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chapter).To(Equal(Chapter{ID: "1", BookID: "3"}))
+		})
+
+		It("checks the type of local ids", func() {
+			var chapter Chapter
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "chapters", "relationships": {
+				"book": {"data": {"lid": "new-book", "type": "comments"}}
+			}}}`), &chapter)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("type comments does not match expected type books of relationship book"))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "chapters", "relationships": {
+				"pages": {"data": [{"lid": "new-page", "type": "comments"}]}
+			}}}`), &chapter)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("type comments does not match expected type pages of relationship pages"))
+		})
+
+		It("removes duplicate local ids of unique relations", func() {
+			var chapter Chapter
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "chapters", "relationships": {
+				"pages": {"data": [{"lid": "x", "type": "pages"}, {"lid": "y", "type": "pages"}, {"lid": "x", "type": "pages"}]}
+			}}}`), &chapter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chapter.PageLIDs).To(Equal([]string{"x", "y"}))
+		})
+
+		It("keeps the ids if a to-many target does not support local ids", func() {
+			review := Review{ID: "1", TagIDs: []string{"3"}}
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "reviews", "relationships": {
+				"tags": {"data": [{"lid": "new-tag", "type": "tags"}]}
+			}}}`), &review)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("target struct must implement interface UnmarshalLocalIDRelations"))
+			Expect(review.TagIDs).To(Equal([]string{"3"}))
+		})
+
+		It("returns the error of the to-many setter", func() {
+			var review Review
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "reviews", "relationships": {
+				"labels": {"data": [{"id": "2", "type": "labels"}]}
+			}}}`), &review)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("There is no to-many relationship named labels"))
+		})
+
+		It("errors if the target does not support local ids", func() {
+			var posts []Post
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "posts", "relationships": {
+				"author": {"data": {"lid": "new-user", "type": "users"}}
+			}}}`), &posts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("target struct must implement interface UnmarshalLocalIDRelations"))
+		})
+	})
+
+	Context("when unmarshaling relations with a strict type", func() {
+		reviewMap := func(authorType, tagType string) map[string]interface{} {
+			return map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "reviews",
+					"relationships": map[string]interface{}{
+						"author": map[string]interface{}{
+							"data": map[string]interface{}{"id": "2", "type": authorType},
+						},
+						"tags": map[string]interface{}{
+							"data": []interface{}{
+								map[string]interface{}{"id": "3", "type": tagType},
+							},
+						},
+					},
+				},
+			}
+		}
+
+		It("unmarshals relations with the expected types", func() {
+			var review Review
+			err := Unmarshal(reviewMap("users", "tags"), &review)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(review).To(Equal(Review{ID: "1", AuthorID: "2", TagIDs: []string{"3"}}))
+		})
+
+		It("removes duplicate ids of unique relations in order", func() {
+			var review Review
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "reviews", "relationships": {"tags": {"data": [
+				{"id": "3", "type": "tags"}, {"id": "1", "type": "tags"}, {"id": "3", "type": "tags"}, {"id": "2", "type": "tags"}, {"id": "1", "type": "tags"}
+			]}}}}`), &review)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(review.TagIDs).To(Equal([]string{"3", "1", "2"}))
+		})
+
+		It("errors on a mismatched to-one type", func() {
+			var review Review
+			err := Unmarshal(reviewMap("comments", "tags"), &review)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("type comments does not match expected type users of relationship author"))
+		})
+
+		It("errors on a mismatched to-many type", func() {
+			var review Review
+			err := Unmarshal(reviewMap("users", "posts"), &review)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("type posts does not match expected type tags of relationship tags"))
+		})
 	})
 
 	Context("when unmarshaling into an existing slice", func() {
@@ -356,6 +827,346 @@ var _ = Describe("Unmarshal", func() {
 			Expect(err).To(BeNil())
 			Expect(posts).To(Equal([]Post{Post{ID: 1, Title: "New Title"}}))
 		})
+
+		It("reuses the capacity of the slice", func() {
+			postMap := map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "1", "type": "simplePosts"},
+					map[string]interface{}{"id": "2", "type": "simplePosts"},
+				},
+			}
+			posts := make([]SimplePost, 0, 2)
+			backingArray := posts[:cap(posts)]
+			err := Unmarshal(postMap, &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]SimplePost{SimplePost{ID: "1"}, SimplePost{ID: "2"}}))
+			Expect(&posts[0]).To(BeIdenticalTo(&backingArray[0]))
+			Expect(&posts[1]).To(BeIdenticalTo(&backingArray[1]))
+
+			posts = posts[:0]
+			postMap["data"] = append(postMap["data"].([]interface{}), map[string]interface{}{"id": "3", "type": "simplePosts"})
+			err = Unmarshal(postMap, &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(HaveLen(3))
+			Expect(&posts[0]).ToNot(BeIdenticalTo(&backingArray[0]))
+		})
+	})
+
+	Context("when unmarshaling ids", func() {
+		It("sets numeric ids", func() {
+			var posts []Post
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "42", "type": "posts"}}`), &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]Post{Post{ID: 42}}))
+		})
+
+		It("returns errors of SetID", func() {
+			var posts []Post
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "4a2", "type": "posts"}}`), &posts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid syntax"))
+		})
+	})
+
+	Context("when unmarshaling the raw resource", func() {
+		type AuditedPost struct {
+			Title string
+			Raw   json.RawMessage `json:"-" jsonapi:"raw"`
+		}
+
+		It("sets the JSON of the resource object", func() {
+			var posts []AuditedPost
+			err := UnmarshalFromJSON([]byte(`{"data": [{"type": "auditedPosts", "attributes": {"title": "First"}}, {"type": "auditedPosts", "attributes": {"title": "Second"}}]}`), &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(HaveLen(2))
+			Expect(posts[0].Title).To(Equal("First"))
+			Expect([]byte(posts[0].Raw)).To(MatchJSON(`{"type": "auditedPosts", "attributes": {"title": "First"}}`))
+			Expect([]byte(posts[1].Raw)).To(MatchJSON(`{"type": "auditedPosts", "attributes": {"title": "Second"}}`))
+		})
+
+		It("errors on raw fields of other types", func() {
+			type BrokenPost struct {
+				Raw string `jsonapi:"raw"`
+			}
+
+			var posts []BrokenPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "brokenPosts"}}`), &posts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("raw field Raw must be an exported json.RawMessage"))
+		})
+
+		It("errors on unexported raw fields", func() {
+			type HiddenPost struct {
+				Title string
+				raw   json.RawMessage `jsonapi:"raw"`
+			}
+
+			var posts []HiddenPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "hiddenPosts", "attributes": {"title": "First"}}}`), &posts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("raw field raw must be an exported json.RawMessage"))
+		})
+	})
+
+	Context("when unmarshaling resource meta", func() {
+		type Document struct {
+			Title    string
+			Version  int    `json:"-" jsonapi:"meta=version"`
+			LockedBy string `json:"-" jsonapi:"meta=lock.owner"`
+		}
+
+		It("sets fields from meta paths", func() {
+			var documents []Document
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "documents", "attributes": {"title": "Test"}, "meta": {"version": 3, "lock": {"owner": "nino"}}}}`), &documents)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(documents).To(Equal([]Document{Document{Title: "Test", Version: 3, LockedBy: "nino"}}))
+		})
+
+		It("ignores missing meta paths", func() {
+			var documents []Document
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "documents", "meta": {"lock": "nino"}}}`), &documents)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(documents).To(Equal([]Document{Document{}}))
+		})
+
+		It("errors on meta values with a wrong type", func() {
+			var documents []Document
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "documents", "meta": {"version": "three"}}}`), &documents)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Version'. Value 'three' had wrong type"))
+		})
+
+		It("sets fields without json:\"-\"", func() {
+			type Sheet struct {
+				Title   string
+				Version int `jsonapi:"meta=version"`
+			}
+
+			var sheets []Sheet
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "sheets", "attributes": {"title": "Test"}, "meta": {"version": 3}}}`), &sheets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sheets).To(Equal([]Sheet{Sheet{Title: "Test", Version: 3}}))
+		})
+
+		It("errors on unexported meta fields", func() {
+			type HiddenDocument struct {
+				Title   string
+				version int `jsonapi:"meta=version"`
+			}
+
+			var documents []HiddenDocument
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "hiddenDocuments", "meta": {"version": 3}}}`), &documents)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("meta field version must be exported"))
+		})
+	})
+
+	Context("when unmarshaling the original id", func() {
+		It("sets the id and the original id", func() {
+			var post VersionedPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "7", "type": "versionedPosts", "attributes": {"title": "Test"}}}`), &post)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(post).To(Equal(VersionedPost{ID: 7, OriginalID: "7", Title: "Test"}))
+		})
+
+		It("keeps the original id empty without an id", func() {
+			var post VersionedPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "versionedPosts", "attributes": {"title": "Test"}}}`), &post)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(post).To(Equal(VersionedPost{Title: "Test"}))
+		})
+
+		It("checks the original id field without an id", func() {
+			type BrokenPost struct {
+				OriginalID int `jsonapi:"originalid"`
+			}
+
+			var posts []BrokenPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "brokenPosts"}}`), &posts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("originalid field OriginalID must be an exported string"))
+		})
+	})
+
+	Context("when unmarshaling with a mask", func() {
+		It("returns the attributes and relationships of every model", func() {
+			postMap := map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{
+						"id":   "1",
+						"type": "posts",
+						"attributes": map[string]interface{}{
+							"title": "New Title",
+						},
+						"relationships": map[string]interface{}{
+							"author": map[string]interface{}{
+								"data": map[string]interface{}{
+									"id":   "2",
+									"type": "users",
+								},
+							},
+						},
+					},
+					map[string]interface{}{
+						"id":   "2",
+						"type": "posts",
+						"relationships": map[string]interface{}{
+							"comments": map[string]interface{}{
+								"data": []interface{}{},
+							},
+						},
+					},
+				},
+			}
+			posts := []Post{Post{ID: 1, Title: "Old Title", CommentsIDs: []int{1}}}
+			masks, err := UnmarshalWithMask(postMap, &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]Post{
+				Post{ID: 1, Title: "New Title", CommentsIDs: []int{1}, AuthorID: sql.NullInt64{Valid: true, Int64: 2}},
+				Post{ID: 2, CommentsIDs: []int{}},
+			}))
+			Expect(masks).To(Equal([]map[string]bool{
+				{"title": true, "author": true},
+				{"comments": true},
+			}))
+		})
+
+		It("returns an empty mask for a single model without fields", func() {
+			var post SimplePost
+			masks, err := UnmarshalWithMask(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "simplePosts",
+				},
+			}, &post)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(masks).To(Equal([]map[string]bool{{}}))
+		})
+
+		It("errors like Unmarshal", func() {
+			var posts []SimplePost
+			_, err := UnmarshalWithMask(map[string]interface{}{}, &posts)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when unmarshaling with a split", func() {
+		It("splits created and updated models", func() {
+			postMap := map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{
+						"type": "simplePosts",
+						"attributes": map[string]interface{}{
+							"title": "Created",
+						},
+					},
+					map[string]interface{}{
+						"id":   "1",
+						"type": "simplePosts",
+						"attributes": map[string]interface{}{
+							"title": "Updated existing",
+						},
+					},
+					map[string]interface{}{
+						"id":   "2",
+						"type": "simplePosts",
+						"attributes": map[string]interface{}{
+							"title": "Updated new",
+						},
+					},
+				},
+			}
+			posts := []SimplePost{SimplePost{ID: "1", Title: "Existing"}}
+			created, updated, err := UnmarshalSplit(postMap, &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(HaveLen(3))
+			Expect(created).To(HaveLen(1))
+			Expect(created[0].Interface()).To(Equal(SimplePost{Title: "Created"}))
+			Expect(updated).To(HaveLen(2))
+			Expect(updated[0].Interface()).To(Equal(SimplePost{ID: "1", Title: "Updated existing"}))
+			Expect(updated[1].Interface()).To(Equal(SimplePost{ID: "2", Title: "Updated new"}))
+
+			// the values are the elements of the target slice
+			updated[0].Addr().Interface().(*SimplePost).Text = "Changed"
+			Expect(posts[0].Text).To(Equal("Changed"))
+		})
+
+		It("splits into a slice of pointers", func() {
+			var posts []*SimplePost
+			created, updated, err := UnmarshalSplit(map[string]interface{}{
+				"data": map[string]interface{}{
+					"type": "simplePosts",
+				},
+			}, &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updated).To(BeEmpty())
+			Expect(created).To(HaveLen(1))
+			Expect(created[0].Interface()).To(Equal(posts[0]))
+		})
+
+		It("splits a single model into a struct", func() {
+			var post SimplePost
+			created, updated, err := UnmarshalSplit(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "1",
+					"type": "simplePosts",
+				},
+			}, &post)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(BeEmpty())
+			Expect(updated).To(HaveLen(1))
+			Expect(updated[0].Interface()).To(Equal(SimplePost{ID: "1"}))
+		})
+
+		It("errors on multiple models for a struct", func() {
+			var post SimplePost
+			_, _, err := UnmarshalSplit(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"type": "simplePosts"},
+					map[string]interface{}{"id": "1", "type": "simplePosts"},
+				},
+			}, &post)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("a struct target can only be split with a single model, use a slice for multiple models"))
+			Expect(post).To(Equal(SimplePost{}))
+		})
+	})
+
+	Context("when unmarshaling into a field of a wrapper struct", func() {
+		type Page struct {
+			Number int
+			Items  []SimplePost
+		}
+
+		postsMap := map[string]interface{}{
+			"data": []interface{}{
+				map[string]interface{}{"id": "1", "type": "simplePosts", "attributes": map[string]interface{}{"title": "First"}},
+				map[string]interface{}{"id": "2", "type": "simplePosts", "attributes": map[string]interface{}{"title": "Second"}},
+			},
+		}
+
+		It("unmarshals into the named field", func() {
+			page := Page{Number: 1}
+			err := UnmarshalIntoField(postsMap, &page, "Items")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page).To(Equal(Page{Number: 1, Items: []SimplePost{
+				SimplePost{ID: "1", Title: "First"},
+				SimplePost{ID: "2", Title: "Second"},
+			}}))
+		})
+
+		It("errors on unknown fields", func() {
+			var page Page
+			err := UnmarshalIntoField(postsMap, &page, "Posts")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("expected struct Page to have field Posts"))
+		})
+
+		It("errors on wrappers that are not struct pointers", func() {
+			var posts []SimplePost
+			err := UnmarshalIntoField(postsMap, &posts, "Items")
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Context("when unmarshaling with null values", func() {
@@ -474,6 +1285,270 @@ var _ = Describe("Unmarshal", func() {
 		})
 	})
 
+	Context("when unmarshalling attributes with tag names in a different case", func() {
+		type Member struct {
+			Name string `jsonapi:"name=firstName"`
+		}
+
+		type Nicknames struct {
+			First  string `jsonapi:"name=nickName"`
+			Second string `jsonapi:"name=nickname"`
+			Third  string `jsonapi:"name=alias"`
+			Fourth string `jsonapi:"name=ALIAS"`
+		}
+
+		It("uses the exact tag name", func() {
+			var members []Member
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "members", "attributes": {"firstName": "Nino"}}}`), &members)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(members).To(Equal([]Member{Member{Name: "Nino"}}))
+		})
+
+		It("falls back to a case insensitive tag name", func() {
+			var members []Member
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "members", "attributes": {"firstname": "Nino"}}}`), &members)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(members).To(Equal([]Member{Member{Name: "Nino"}}))
+		})
+
+		It("prefers exact matches over case insensitive ones", func() {
+			var nicknames []Nicknames
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "nicknames", "attributes": {"nickname": "Nino"}}}`), &nicknames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nicknames).To(Equal([]Nicknames{Nicknames{Second: "Nino"}}))
+		})
+
+		It("errors on ambiguous case insensitive tag names", func() {
+			var nicknames []Nicknames
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "nicknames", "attributes": {"Alias": "Nino"}}}`), &nicknames)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("attribute Alias matches the tags of multiple fields of struct Nicknames"))
+		})
+	})
+
+	Context("when unmarshalling attributes with a range", func() {
+		type Athlete struct {
+			Name  string   `jsonapi:"max=5"`
+			Age   int      `jsonapi:"min=0;max=150"`
+			Score float64  `jsonapi:"min=0.5"`
+			Teams []string `jsonapi:"min=1"`
+		}
+
+		It("unmarshals values within the range", func() {
+			var athletes []Athlete
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "athletes", "attributes": {"name": "Nino", "age": 150, "score": 0.5, "teams": ["a"]}}}`), &athletes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(athletes).To(Equal([]Athlete{Athlete{Name: "Nino", Age: 150, Score: 0.5, Teams: []string{"a"}}}))
+		})
+
+		It("errors on numbers out of range", func() {
+			var athletes []Athlete
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "athletes", "attributes": {"age": -1}}}`), &athletes)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Age'. Value -1 is less than the minimum of 0"))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"type": "athletes", "attributes": {"age": 151}}}`), &athletes)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Age'. Value 151 is greater than the maximum of 150"))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"type": "athletes", "attributes": {"score": 0.4}}}`), &athletes)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Score'. Value 0.4 is less than the minimum of 0.5"))
+		})
+
+		It("compares json.Number values by value", func() {
+			type Invoice struct {
+				Amount json.Number `jsonapi:"max=100"`
+			}
+
+			var invoices []Invoice
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "invoices", "attributes": {"amount": 99.5}}}`), &invoices)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(invoices).To(Equal([]Invoice{Invoice{Amount: "99.5"}}))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"type": "invoices", "attributes": {"amount": 100000}}}`), &invoices)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Amount'. Value 100000 is greater than the maximum of 100"))
+		})
+
+		It("errors on lengths out of range", func() {
+			var athletes []Athlete
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "athletes", "attributes": {"name": "Marcel"}}}`), &athletes)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Name'. Length 6 is greater than the maximum of 5"))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"type": "athletes", "attributes": {"teams": []}}}`), &athletes)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Teams'. Length 0 is less than the minimum of 1"))
+		})
+	})
+
+	Context("when unmarshalling attributes with a custom error message", func() {
+		type Subscriber struct {
+			Email   string `jsonapi:"msg=Please provide a valid email"`
+			Age     int    `jsonapi:"min=18;msg=You must be of age"`
+			Name    string
+			Created time.Time `jsonapi:"msg=Please send a date"`
+		}
+
+		It("uses the custom message for failing fields", func() {
+			var subscribers []Subscriber
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "subscribers", "attributes": {"email": 42}}}`), &subscribers)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Email'. Please provide a valid email"))
+			unmarshalError, ok := err.(UnmarshalError)
+			Expect(ok).To(BeTrue())
+			Expect(unmarshalError.Err.Error()).To(Equal("Value '42' had wrong type"))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"type": "subscribers", "attributes": {"age": 17}}}`), &subscribers)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Age'. You must be of age"))
+
+			err = UnmarshalFromJSON([]byte(`{"data": {"type": "subscribers", "attributes": {"created": "yesterday"}}}`), &subscribers)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Created'. Please send a date"))
+		})
+
+		It("uses the generic message for other fields", func() {
+			var subscribers []Subscriber
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "subscribers", "attributes": {"name": 42}}}`), &subscribers)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Name'. Value '42' had wrong type"))
+		})
+	})
+
+	Context("when unmarshalling with a field resolver", func() {
+		It("uses the resolved fields", func() {
+			var posts []LegacyPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "legacyPosts", "attributes": {"hdr": "Header", "title": "Text"}}}`), &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]LegacyPost{LegacyPost{ID: "1", Title: "Header", Body: "Text"}}))
+		})
+
+		It("errors on invalid resolved field indexes", func() {
+			var posts []LegacyPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "legacyPosts", "attributes": {"broken": "Text"}}}`), &posts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("field index [5] resolved for attribute broken is invalid for struct LegacyPost"))
+		})
+
+		It("falls back to the default mapping", func() {
+			var posts []LegacyPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "legacyPosts", "attributes": {"body": "Text"}}}`), &posts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(posts).To(Equal([]LegacyPost{LegacyPost{ID: "1", Body: "Text"}}))
+		})
+	})
+
+	Context("when unmarshalling slice attributes", func() {
+		type Article struct {
+			Tags     []string
+			Keywords []string `jsonapi:"singleormany"`
+		}
+
+		It("unmarshals arrays", func() {
+			var articles []Article
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "articles", "attributes": {"tags": ["a", "b"], "keywords": ["c", "d"]}}}`), &articles)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(articles).To(Equal([]Article{Article{Tags: []string{"a", "b"}, Keywords: []string{"c", "d"}}}))
+		})
+
+		It("wraps a single value for singleormany fields", func() {
+			var articles []Article
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "articles", "attributes": {"keywords": "c"}}}`), &articles)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(articles).To(Equal([]Article{Article{Keywords: []string{"c"}}}))
+		})
+
+		It("errors on a single value for other slice fields", func() {
+			var articles []Article
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "articles", "attributes": {"tags": "a"}}}`), &articles)
+			Expect(err).To(HaveOccurred())
+			unmarshalError, ok := err.(UnmarshalError)
+			Expect(ok).To(BeTrue())
+			Expect(unmarshalError.ExpectedKind).To(Equal(reflect.Slice))
+			Expect(unmarshalError.ActualType).To(Equal("string"))
+		})
+	})
+
+	Context("when unmarshalling into json.Number fields", func() {
+		preciseJSON := `
+			{
+				"data": {
+					"id": "test",
+					"type": "precisePosts",
+					"attributes": {
+						"title": "Blubb",
+						"amount": 1234567.0815
+					}
+				}
+			}
+		`
+
+		It("converts float64 values", func() {
+			var precisePosts []PrecisePost
+			err := UnmarshalFromJSON([]byte(preciseJSON), &precisePosts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(precisePosts).To(HaveLen(1))
+			Expect(precisePosts[0].Amount).To(Equal(json.Number("1234567.0815")))
+		})
+
+		It("passes json.Number values through", func() {
+			var (
+				ctx          map[string]interface{}
+				precisePosts []PrecisePost
+			)
+			decoder := json.NewDecoder(strings.NewReader(preciseJSON))
+			decoder.UseNumber()
+			Expect(decoder.Decode(&ctx)).To(Succeed())
+
+			err := Unmarshal(ctx, &precisePosts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(precisePosts).To(HaveLen(1))
+			Expect(precisePosts[0].Amount).To(Equal(json.Number("1234567.0815")))
+		})
+
+		It("errors on non-number values", func() {
+			var precisePosts []PrecisePost
+			err := UnmarshalFromJSON([]byte(`{"data": {"type": "precisePosts", "attributes": {"amount": "many"}}}`), &precisePosts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'Amount'. Value 'many' had wrong type"))
+		})
+	})
+
+	Context("when unmarshalling enum values with a fallback", func() {
+		It("sets known values", func() {
+			var post StatusPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "statusPosts", "attributes": {"status": "draft", "strictState": "published"}}}`), &post)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(post).To(Equal(StatusPost{ID: "1", Status: "draft", StrictState: "published"}))
+		})
+
+		It("sets the fallback for unknown values", func() {
+			var post StatusPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "statusPosts", "attributes": {"status": "archived"}}}`), &post)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(post).To(Equal(StatusPost{ID: "1", Status: "unknown"}))
+		})
+
+		It("errors on unknown values without fallback", func() {
+			var post StatusPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "statusPosts", "attributes": {"strictState": "archived"}}}`), &post)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not set field 'StrictState'. unknown post status archived"))
+		})
+
+		It("errors on values of a wrong type despite a fallback", func() {
+			var post StatusPost
+			err := UnmarshalFromJSON([]byte(`{"data": {"id": "1", "type": "statusPosts", "attributes": {"status": 42}}}`), &post)
+			Expect(err).To(HaveOccurred())
+			unmarshalError, ok := err.(UnmarshalError)
+			Expect(ok).To(BeTrue())
+			Expect(unmarshalError.Field).To(Equal("Status"))
+			Expect(unmarshalError.ActualType).To(Equal("number"))
+		})
+	})
+
 	Context("SQL Null-Types", func() {
 		var nullPosts []SQLNullPost
 